@@ -0,0 +1,120 @@
+// Package cbor mirrors package j2n's overflow-preserving semantics for CBOR:
+// any map keys that are not marshaled directly into the fields of a struct
+// are put into a field with type UnknownFields, so they survive an
+// Unmarshal/Marshal round trip.
+//
+// Use it exactly like j2n itself, but with `cbor:"-"` in place of
+// `json:"-"`:
+//
+//  type CatData struct {
+//  	Name string        `cbor:"name"`
+//  	Rest UnknownFields `cbor:"-"`
+//  }
+package cbor
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/Brawaru/j2n/internal/overflow"
+	"github.com/fxamacker/cbor/v2"
+)
+
+// UnknownFields holds the CBOR map keys that were not explicitly named in
+// the destination struct, preserved as raw CBOR so they survive a round
+// trip unmodified.
+type UnknownFields map[string]cbor.RawMessage
+
+var unknownFieldsType = reflect.TypeOf((UnknownFields)(nil))
+
+// Unmarshal parses the CBOR-encoded data into the struct pointed to by v.
+//
+// This behaves exactly like cbor.Unmarshal, but any extra map entries that
+// are not explicitly named in the struct are unmarshalled into the
+// 'Overflow' field.
+func Unmarshal(data []byte, v interface{}) error {
+	overflow, err := resetOverflowMap(v)
+	if err != nil {
+		return err
+	}
+
+	if err := cbor.Unmarshal(data, &overflow); err != nil {
+		return err
+	}
+
+	if err := cbor.Unmarshal(data, v); err != nil {
+		return err
+	}
+
+	namedFieldsData, err := cbor.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	namedFieldsMap := make(UnknownFields)
+	if err := cbor.Unmarshal(namedFieldsData, &namedFieldsMap); err != nil {
+		return err
+	}
+
+	for k := range namedFieldsMap {
+		delete(overflow, k)
+	}
+
+	return nil
+}
+
+// Marshal returns the CBOR encoding of v, which must be a struct.
+//
+// This behaves exactly like cbor.Marshal, but ensures that any extra
+// entries mentioned in v's UnknownFields field are output alongside the
+// explicitly named struct fields.
+func Marshal(v interface{}) ([]byte, error) {
+	result := make(UnknownFields)
+
+	namedFieldsData, err := cbor.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := cbor.Unmarshal(namedFieldsData, &result); err != nil {
+		return nil, err
+	}
+
+	overflow, err := getOverflowMap(v)
+	if err != nil {
+		return nil, err
+	}
+
+	for k, raw := range overflow {
+		if _, ok := result[k]; ok {
+			return nil, fmt.Errorf("named field present in overflow: '%s'", k)
+		}
+		result[k] = raw
+	}
+
+	return cbor.Marshal(result)
+}
+
+func resetOverflowMap(v interface{}) (UnknownFields, error) {
+	value, err := getOverflowFieldValue(v)
+	if err != nil {
+		return nil, err
+	}
+
+	overflow := make(UnknownFields)
+	value.Set(reflect.ValueOf(overflow))
+	return overflow, nil
+}
+
+func getOverflowMap(v interface{}) (UnknownFields, error) {
+	value, err := getOverflowFieldValue(v)
+	if err != nil {
+		return nil, err
+	}
+
+	return value.Interface().(UnknownFields), nil
+}
+
+func getOverflowFieldValue(v interface{}) (reflect.Value, error) {
+	return overflow.FieldValue(v, unknownFieldsType, "cbor")
+}
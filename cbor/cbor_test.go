@@ -0,0 +1,50 @@
+package cbor
+
+import (
+	"testing"
+
+	"github.com/fxamacker/cbor/v2"
+)
+
+type catData struct {
+	Name string        `cbor:"name"`
+	Rest UnknownFields `cbor:"-"`
+}
+
+func TestUnmarshalMarshalRoundTrip(t *testing.T) {
+	input, err := cbor.Marshal(map[string]interface{}{
+		"name": "Tom",
+		"age":  9,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var cat catData
+	if err := Unmarshal(input, &cat); err != nil {
+		t.Fatal(err)
+	}
+
+	if cat.Name != "Tom" {
+		t.Errorf("Name = %q, want Tom", cat.Name)
+	}
+	if _, ok := cat.Rest["age"]; !ok {
+		t.Error("missing overflow key 'age'")
+	}
+
+	out, err := Marshal(cat)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var roundTripped map[string]interface{}
+	if err := cbor.Unmarshal(out, &roundTripped); err != nil {
+		t.Fatal(err)
+	}
+	if roundTripped["name"] != "Tom" {
+		t.Errorf("marshaled output name = %v, want Tom", roundTripped["name"])
+	}
+	if _, ok := roundTripped["age"]; !ok {
+		t.Error("marshaled output missing overflow key 'age'")
+	}
+}
@@ -0,0 +1,281 @@
+// Command j2ngen generates MarshalJSON/UnmarshalJSON methods for structs
+// that embed j2n.UnknownFields, using the same overflow-preserving
+// semantics as j2n.MarshalJSON/j2n.UnmarshalJSON but without reflection or
+// the triple marshal/unmarshal round trip.
+//
+// Usage:
+//
+//	j2ngen -type=Cat [-output=cat_j2n.go] [package-directory]
+//
+// It is typically invoked via a go:generate directive placed next to the
+// type it targets:
+//
+//	//go:generate j2ngen -type=Cat
+package main
+
+import (
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"text/template"
+)
+
+func main() {
+	if err := run(); err != nil {
+		fmt.Fprintln(os.Stderr, "j2ngen:", err)
+		os.Exit(1)
+	}
+}
+
+func run() error {
+	var typeNames string
+	var output string
+	flag.StringVar(&typeNames, "type", "", "comma-separated list of struct type names to generate for (required)")
+	flag.StringVar(&output, "output", "", "output file name (default: <first type, lowercased>_j2n.go)")
+	flag.Parse()
+
+	if typeNames == "" {
+		return fmt.Errorf("-type is required")
+	}
+
+	dir := "."
+	if flag.NArg() > 0 {
+		dir = flag.Arg(0)
+	}
+
+	names := strings.Split(typeNames, ",")
+
+	pkgName, structs, err := parseStructs(dir, names)
+	if err != nil {
+		return err
+	}
+
+	src, err := generate(pkgName, structs)
+	if err != nil {
+		return err
+	}
+
+	if output == "" {
+		output = strings.ToLower(names[0]) + "_j2n.go"
+	}
+
+	return os.WriteFile(filepath.Join(dir, output), src, 0o644)
+}
+
+// field is a named struct field that participates in JSON encoding.
+type field struct {
+	GoName  string
+	JSONKey string
+}
+
+// structType describes one struct targeted by -type.
+type structType struct {
+	Name          string
+	Fields        []field
+	OverflowField string
+}
+
+// parseStructs parses the Go package in dir and returns the package name
+// and the requested struct types, in the order given by names.
+func parseStructs(dir string, names []string) (string, []*structType, error) {
+	fset := token.NewFileSet()
+	pkgs, err := parser.ParseDir(fset, dir, nil, parser.ParseComments)
+	if err != nil {
+		return "", nil, err
+	}
+
+	var files []*ast.File
+	var pkgName string
+	for name, pkg := range pkgs {
+		if strings.HasSuffix(name, "_test") {
+			continue
+		}
+		pkgName = name
+		for _, f := range pkg.Files {
+			files = append(files, f)
+		}
+	}
+	if pkgName == "" {
+		return "", nil, fmt.Errorf("no Go package found in %s", dir)
+	}
+
+	decls := make(map[string]*ast.StructType)
+	for _, f := range files {
+		for _, decl := range f.Decls {
+			genDecl, ok := decl.(*ast.GenDecl)
+			if !ok || genDecl.Tok != token.TYPE {
+				continue
+			}
+			for _, spec := range genDecl.Specs {
+				typeSpec, ok := spec.(*ast.TypeSpec)
+				if !ok {
+					continue
+				}
+				structType, ok := typeSpec.Type.(*ast.StructType)
+				if !ok {
+					continue
+				}
+				decls[typeSpec.Name.Name] = structType
+			}
+		}
+	}
+
+	structs := make([]*structType, 0, len(names))
+	for _, name := range names {
+		name = strings.TrimSpace(name)
+		astStruct, ok := decls[name]
+		if !ok {
+			return "", nil, fmt.Errorf("no struct type %q found in %s", name, dir)
+		}
+
+		st, err := toStructType(name, astStruct)
+		if err != nil {
+			return "", nil, err
+		}
+		structs = append(structs, st)
+	}
+
+	return pkgName, structs, nil
+}
+
+func toStructType(name string, astStruct *ast.StructType) (*structType, error) {
+	st := &structType{Name: name}
+
+	for _, f := range astStruct.Fields.List {
+		if len(f.Names) == 0 {
+			return nil, fmt.Errorf("%s: embedded fields are not supported by j2ngen", name)
+		}
+
+		tag := ""
+		if f.Tag != nil {
+			tag = strings.Trim(f.Tag.Value, "`")
+		}
+		jsonTag, hasJSONTag := lookupTag(tag, "json")
+
+		if isUnknownFields(f.Type) {
+			if hasJSONTag && jsonTag != "-" {
+				return nil, fmt.Errorf("%s: UnknownFields field %q must be tagged `json:\"-\"`", name, f.Names[0].Name)
+			}
+			if st.OverflowField != "" {
+				return nil, fmt.Errorf("%s: multiple UnknownFields fields", name)
+			}
+			st.OverflowField = f.Names[0].Name
+			continue
+		}
+
+		if hasJSONTag && jsonTag == "-" {
+			continue
+		}
+
+		for _, fieldName := range f.Names {
+			key := fieldName.Name
+			if hasJSONTag {
+				if k := strings.Split(jsonTag, ",")[0]; k != "" {
+					key = k
+				}
+			}
+			st.Fields = append(st.Fields, field{GoName: fieldName.Name, JSONKey: key})
+		}
+	}
+
+	if st.OverflowField == "" {
+		return nil, fmt.Errorf("%s: no UnknownFields field found", name)
+	}
+
+	return st, nil
+}
+
+// isUnknownFields reports whether t refers to a type named UnknownFields,
+// either bare (t is defined in the package being generated for) or
+// qualified as pkg.UnknownFields.
+func isUnknownFields(t ast.Expr) bool {
+	switch t := t.(type) {
+	case *ast.Ident:
+		return t.Name == "UnknownFields"
+	case *ast.SelectorExpr:
+		return t.Sel.Name == "UnknownFields"
+	default:
+		return false
+	}
+}
+
+// lookupTag extracts the value of the struct tag key from raw (the tag's
+// literal contents, without surrounding backticks).
+func lookupTag(raw, key string) (value string, ok bool) {
+	return reflect.StructTag(raw).Lookup(key)
+}
+
+func generate(pkgName string, structs []*structType) ([]byte, error) {
+	var buf strings.Builder
+	if err := tmpl.Execute(&buf, struct {
+		Package string
+		Structs []*structType
+	}{
+		Package: pkgName,
+		Structs: structs,
+	}); err != nil {
+		return nil, err
+	}
+
+	return format.Source([]byte(buf.String()))
+}
+
+var tmpl = template.Must(template.New("j2ngen").Parse(`// Code generated by j2ngen. DO NOT EDIT.
+
+package {{.Package}}
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+{{range .Structs}}
+func (v {{.Name}}) MarshalJSON() ([]byte, error) {
+	result := make(map[string]*json.RawMessage, len(v.{{.OverflowField}}))
+	for k, raw := range v.{{.OverflowField}} {
+		result[k] = raw
+	}
+
+{{range .Fields}}	{{.GoName}}JSON, err := json.Marshal(v.{{.GoName}})
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := result["{{.JSONKey}}"]; ok {
+		return nil, fmt.Errorf("named field present in overflow: '%s'", "{{.JSONKey}}")
+	}
+	result["{{.JSONKey}}"] = (*json.RawMessage)(&{{.GoName}}JSON)
+{{end}}
+	return json.Marshal(result)
+}
+
+func (v *{{.Name}}) UnmarshalJSON(data []byte) error {
+	raw := make(map[string]json.RawMessage)
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+{{range .Fields}}	if fieldData, ok := raw["{{.JSONKey}}"]; ok {
+		if err := json.Unmarshal(fieldData, &v.{{.GoName}}); err != nil {
+			return err
+		}
+		delete(raw, "{{.JSONKey}}")
+	}
+{{end}}
+	overflow := make(map[string]*json.RawMessage, len(raw))
+	for k := range raw {
+		fieldData := raw[k]
+		overflow[k] = &fieldData
+	}
+	v.{{.OverflowField}} = overflow
+
+	return nil
+}
+{{end}}
+`))
@@ -0,0 +1,136 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseAndGenerate(t *testing.T) {
+	dir := t.TempDir()
+
+	src := "package sample\n\n" +
+		"import \"encoding/json\"\n\n" +
+		"type UnknownFields map[string]*json.RawMessage\n\n" +
+		"type Cat struct {\n" +
+		"	Name string        `json:\"name\"`\n" +
+		"	Rest UnknownFields `json:\"-\"`\n" +
+		"}\n"
+
+	if err := os.WriteFile(filepath.Join(dir, "cat.go"), []byte(src), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	pkgName, structs, err := parseStructs(dir, []string{"Cat"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pkgName != "sample" {
+		t.Errorf("pkgName = %q, want sample", pkgName)
+	}
+	if len(structs) != 1 {
+		t.Fatalf("got %d structs, want 1", len(structs))
+	}
+	if structs[0].OverflowField != "Rest" {
+		t.Errorf("OverflowField = %q, want Rest", structs[0].OverflowField)
+	}
+	if len(structs[0].Fields) != 1 || structs[0].Fields[0].JSONKey != "name" {
+		t.Errorf("Fields = %+v, want a single {GoName: Name, JSONKey: name}", structs[0].Fields)
+	}
+
+	out, err := generate(pkgName, structs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(out), "func (v Cat) MarshalJSON()") {
+		t.Errorf("generated code missing MarshalJSON method:\n%s", out)
+	}
+	if !strings.Contains(string(out), "func (v *Cat) UnmarshalJSON(data []byte) error") {
+		t.Errorf("generated code missing UnmarshalJSON method:\n%s", out)
+	}
+}
+
+// TestGeneratedCodeRoundTrips writes the generated MarshalJSON/
+// UnmarshalJSON methods into their own throwaway module and actually runs
+// them, to catch template regressions (quoting, escaping, bad field
+// references) that a substring check on the source wouldn't.
+func TestGeneratedCodeRoundTrips(t *testing.T) {
+	goBin, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip("go toolchain not available")
+	}
+
+	dir := t.TempDir()
+
+	catSrc := "package main\n\n" +
+		"import \"encoding/json\"\n\n" +
+		"type UnknownFields map[string]*json.RawMessage\n\n" +
+		"type Cat struct {\n" +
+		"	Name string        `json:\"name\"`\n" +
+		"	Rest UnknownFields `json:\"-\"`\n" +
+		"}\n"
+	if err := os.WriteFile(filepath.Join(dir, "cat.go"), []byte(catSrc), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	pkgName, structs, err := parseStructs(dir, []string{"Cat"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	generated, err := generate(pkgName, structs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "cat_j2n.go"), generated, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	mainSrc := "package main\n\n" +
+		"import (\n" +
+		"	\"encoding/json\"\n" +
+		"	\"fmt\"\n" +
+		"	\"os\"\n" +
+		")\n\n" +
+		"func main() {\n" +
+		"	var cat Cat\n" +
+		"	if err := json.Unmarshal([]byte(`{\"name\":\"Tom\",\"age\":9}`), &cat); err != nil {\n" +
+		"		fmt.Fprintln(os.Stderr, err)\n" +
+		"		os.Exit(1)\n" +
+		"	}\n\n" +
+		"	out, err := json.Marshal(cat)\n" +
+		"	if err != nil {\n" +
+		"		fmt.Fprintln(os.Stderr, err)\n" +
+		"		os.Exit(1)\n" +
+		"	}\n\n" +
+		"	fmt.Print(string(out))\n" +
+		"}\n"
+	if err := os.WriteFile(filepath.Join(dir, "main.go"), []byte(mainSrc), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module j2ngentest\n\ngo 1.21\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	cmd := exec.Command(goBin, "run", ".")
+	cmd.Dir = dir
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("go run failed: %v\n%s", err, output)
+	}
+
+	var got map[string]interface{}
+	if err := json.Unmarshal(output, &got); err != nil {
+		t.Fatalf("output %q is not valid JSON: %v", output, err)
+	}
+	if got["name"] != "Tom" {
+		t.Errorf("name = %v, want Tom", got["name"])
+	}
+	if got["age"] != float64(9) {
+		t.Errorf("age = %v, want 9", got["age"])
+	}
+}
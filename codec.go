@@ -0,0 +1,67 @@
+package j2n
+
+import (
+	"bytes"
+	"encoding/json"
+)
+
+// Codec abstracts the JSON implementation used to marshal and unmarshal the
+// named fields of a struct, so a faster drop-in replacement for
+// encoding/json (such as json-iterator/go or segmentio/encoding) can be used
+// instead, without changing the overflow-preserving semantics of this
+// package.
+type Codec interface {
+	Marshal(v interface{}) ([]byte, error)
+	Unmarshal(data []byte, v interface{}) error
+}
+
+// stdCodec is the default Codec, wrapping encoding/json.
+type stdCodec struct{}
+
+func (stdCodec) Marshal(v interface{}) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+func (stdCodec) Unmarshal(data []byte, v interface{}) error {
+	return json.Unmarshal(data, v)
+}
+
+// numberCodec is like stdCodec, but decodes numbers as json.Number instead
+// of float64. It backs Decoder.UseNumber.
+type numberCodec struct{}
+
+func (numberCodec) Marshal(v interface{}) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+func (numberCodec) Unmarshal(data []byte, v interface{}) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	return dec.Decode(v)
+}
+
+// codec is the package-wide Codec used by UnmarshalJSON and MarshalJSON.
+var codec Codec = stdCodec{}
+
+// SetCodec replaces the Codec used by UnmarshalJSON and MarshalJSON for the
+// rest of the program's lifetime. Passing nil restores the default, which
+// wraps encoding/json. It is not safe to call concurrently with other uses
+// of the package.
+func SetCodec(c Codec) {
+	if c == nil {
+		c = stdCodec{}
+	}
+	codec = c
+}
+
+// UnmarshalJSONWith behaves like UnmarshalJSON, but uses c instead of the
+// package-wide Codec set via SetCodec.
+func UnmarshalJSONWith(c Codec, data []byte, v interface{}) error {
+	return unmarshalJSON(data, v, c)
+}
+
+// MarshalJSONWith behaves like MarshalJSON, but uses c instead of the
+// package-wide Codec set via SetCodec.
+func MarshalJSONWith(c Codec, v interface{}) ([]byte, error) {
+	return marshalJSON(v, c)
+}
@@ -0,0 +1,62 @@
+package j2n
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+type countingCodec struct {
+	marshals   int
+	unmarshals int
+}
+
+func (c *countingCodec) Marshal(v interface{}) ([]byte, error) {
+	c.marshals++
+	return json.Marshal(v)
+}
+
+func (c *countingCodec) Unmarshal(data []byte, v interface{}) error {
+	c.unmarshals++
+	return json.Unmarshal(data, v)
+}
+
+type codecCatData struct {
+	Name string        `json:"name"`
+	Rest UnknownFields `json:"-"`
+}
+
+func TestMarshalUnmarshalJSONWithUsesGivenCodec(t *testing.T) {
+	c := &countingCodec{}
+
+	var cat codecCatData
+	if err := UnmarshalJSONWith(c, []byte(`{"name":"Tom","extra":1}`), &cat); err != nil {
+		t.Fatal(err)
+	}
+	if c.unmarshals == 0 {
+		t.Error("UnmarshalJSONWith did not use the provided Codec")
+	}
+	if _, ok := cat.Rest["extra"]; !ok {
+		t.Error("missing overflow key 'extra'")
+	}
+
+	if _, err := MarshalJSONWith(c, cat); err != nil {
+		t.Fatal(err)
+	}
+	if c.marshals == 0 {
+		t.Error("MarshalJSONWith did not use the provided Codec")
+	}
+}
+
+func TestSetCodecAffectsPlainCalls(t *testing.T) {
+	c := &countingCodec{}
+	SetCodec(c)
+	defer SetCodec(nil)
+
+	var cat codecCatData
+	if err := UnmarshalJSON([]byte(`{"name":"Tom"}`), &cat); err != nil {
+		t.Fatal(err)
+	}
+	if c.unmarshals == 0 {
+		t.Error("UnmarshalJSON did not use the codec set via SetCodec")
+	}
+}
@@ -0,0 +1,90 @@
+package j2n
+
+import (
+	"encoding/json"
+	"io"
+)
+
+// Decoder reads a stream of JSON values, such as the elements of a large
+// JSON array or a newline-delimited JSON log, decoding each one with
+// UnmarshalJSON so that unknown fields are preserved per element.
+//
+// Unlike calling UnmarshalJSON on a whole-file byte slice, Decoder is built
+// on top of json.Decoder and only buffers the bytes of the value currently
+// being read, making it suitable for streams too large to hold in memory at
+// once.
+type Decoder struct {
+	dec   *json.Decoder
+	codec Codec
+}
+
+// NewDecoder returns a new Decoder that reads from r. It uses the
+// package-wide Codec set via SetCodec unless UseNumber is called.
+func NewDecoder(r io.Reader) *Decoder {
+	return &Decoder{dec: json.NewDecoder(r)}
+}
+
+// UseNumber causes the Decoder to decode numbers as json.Number instead of
+// float64. This only affects named fields typed loosely enough to receive a
+// number directly (such as interface{}); overflow values are always kept as
+// raw, unparsed JSON regardless of this setting.
+func (d *Decoder) UseNumber() {
+	d.codec = numberCodec{}
+}
+
+// More reports whether there is another element in the array being parsed.
+// It is provided so callers can drive the surrounding '[' and ']' tokens
+// themselves; see json.Decoder.More.
+func (d *Decoder) More() bool {
+	return d.dec.More()
+}
+
+// Token returns the next JSON token in the input stream; see
+// json.Decoder.Token.
+func (d *Decoder) Token() (json.Token, error) {
+	return d.dec.Token()
+}
+
+// Decode reads the next JSON value from the input and stores it in v via
+// UnmarshalJSON.
+func (d *Decoder) Decode(v interface{}) error {
+	var raw json.RawMessage
+	if err := d.dec.Decode(&raw); err != nil {
+		return err
+	}
+
+	c := d.codec
+	if c == nil {
+		c = codec
+	}
+
+	return unmarshalJSON(raw, v, c)
+}
+
+// Encoder writes a stream of JSON values, encoding each one with
+// MarshalJSON so that unknown fields are preserved per element.
+type Encoder struct {
+	enc *json.Encoder
+}
+
+// NewEncoder returns a new Encoder that writes to w.
+func NewEncoder(w io.Writer) *Encoder {
+	return &Encoder{enc: json.NewEncoder(w)}
+}
+
+// SetIndent instructs the Encoder to indent each subsequent Encode call as
+// per json.Encoder.SetIndent.
+func (e *Encoder) SetIndent(prefix, indent string) {
+	e.enc.SetIndent(prefix, indent)
+}
+
+// Encode writes the JSON encoding of v, as produced by MarshalJSON, followed
+// by a newline.
+func (e *Encoder) Encode(v interface{}) error {
+	data, err := MarshalJSON(v)
+	if err != nil {
+		return err
+	}
+
+	return e.enc.Encode(json.RawMessage(data))
+}
@@ -0,0 +1,73 @@
+package j2n
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+type decoderRecord struct {
+	ID   int           `json:"id"`
+	Rest UnknownFields `json:"-"`
+}
+
+func TestDecoderDecodePreservesOverflowPerElement(t *testing.T) {
+	dec := NewDecoder(strings.NewReader(`{"id":1,"extra":"a"}
+{"id":2,"extra":"b"}
+`))
+
+	var got []decoderRecord
+	for {
+		var rec decoderRecord
+		if err := dec.Decode(&rec); err != nil {
+			break
+		}
+		got = append(got, rec)
+	}
+
+	if len(got) != 2 {
+		t.Fatalf("got %d records, want 2", len(got))
+	}
+
+	for i, rec := range got {
+		want := i + 1
+		if rec.ID != want {
+			t.Errorf("record %d: ID = %d, want %d", i, rec.ID, want)
+		}
+		raw, ok := rec.Rest["extra"]
+		if !ok || raw == nil {
+			t.Errorf("record %d: missing overflow key 'extra'", i)
+		}
+	}
+}
+
+func TestDecoderUseNumberDoesNotAffectOverflow(t *testing.T) {
+	dec := NewDecoder(strings.NewReader(`{"id":1,"extra":1.5}`))
+	dec.UseNumber()
+
+	var rec decoderRecord
+	if err := dec.Decode(&rec); err != nil {
+		t.Fatal(err)
+	}
+
+	raw, ok := rec.Rest["extra"]
+	if !ok || raw == nil {
+		t.Fatal("missing overflow key 'extra'")
+	}
+	if string(*raw) != "1.5" {
+		t.Errorf("overflow value = %s, want exact raw bytes 1.5", *raw)
+	}
+
+	var encoder strings.Builder
+	enc := NewEncoder(&encoder)
+	if err := enc.Encode(rec); err != nil {
+		t.Fatal(err)
+	}
+	var roundTripped map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(encoder.String()), &roundTripped); err != nil {
+		t.Fatal(err)
+	}
+	if string(roundTripped["extra"]) != "1.5" {
+		t.Errorf("round-tripped extra = %s, want 1.5", roundTripped["extra"])
+	}
+}
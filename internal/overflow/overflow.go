@@ -0,0 +1,56 @@
+// Package overflow implements the reflection walk shared by j2n and its
+// format-specific siblings (j2n/yaml, j2n/cbor): finding the single field of
+// a struct that acts as the catch-all bucket for keys not explicitly named
+// elsewhere in the struct.
+package overflow
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+// FieldValue returns the settable reflect.Value of v's field whose type is
+// fieldType, requiring that field to be tagged `tagKey:"-"` so the format's
+// own marshaller ignores it. v must be a struct, or a pointer to one.
+func FieldValue(v interface{}, fieldType reflect.Type, tagKey string) (reflect.Value, error) {
+	value := reflect.ValueOf(v)
+
+	// Unwrap the pointer if necessary
+	if value.Type().Kind() == reflect.Ptr {
+		value = value.Elem()
+	}
+
+	// Check that we're dealing with a struct
+	if value.Type().Kind() != reflect.Struct {
+		return reflect.Value{}, fmt.Errorf("expected struct, got %s", value.Type().Kind())
+	}
+
+	var field reflect.Value
+	fieldIndex := -1
+	for i := 0; i < value.NumField(); i++ {
+		f := value.Field(i)
+
+		if f.Type() == fieldType {
+			if fieldIndex == -1 {
+				field = f
+				fieldIndex = i
+			} else {
+				return reflect.Value{}, errors.New("multiple unknown fields")
+			}
+		}
+	}
+
+	// Check that we actually found the field
+	if fieldIndex == -1 {
+		return reflect.Value{}, errors.New("field is not defined")
+	}
+
+	// And that it has a tag ensuring that it is omitted from the standard
+	// marshaller's output
+	if val, ok := value.Type().Field(fieldIndex).Tag.Lookup(tagKey); !ok || val != "-" {
+		return reflect.Value{}, fmt.Errorf("unknown fields must be ignored by the standard marshaller (use `%s:\"-\"`)", tagKey)
+	}
+
+	return field, nil
+}
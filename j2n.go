@@ -3,7 +3,22 @@
 // into a field with type UnknownFields.
 //
 // This means that fields that are not explicitly named in the struct will
-// survive an Unmarshal/Marshal round trip.
+// survive an Unmarshal/Marshal round trip. This holds however deeply the
+// struct is nested: any field (or slice/map of such) that is itself a struct
+// declaring its own UnknownFields field has its unknown keys preserved too.
+//
+// A struct field of type FieldSet tagged `json:"-"` additionally records,
+// per JSON key, whether that key was present in the input and whether it
+// was null, letting callers distinguish a missing field from one explicitly
+// set to null or to its zero value. See FieldSet for details.
+//
+// UnmarshalJSONStrict builds on UnmarshalJSON to require specific fields,
+// reject unknown fields outright, or run a callback for every unknown field,
+// instead of silently collecting them into UnknownFields.
+//
+// Marshal/Unmarshal can be adapted to a JSON implementation other than
+// encoding/json by implementing Codec, and Decoder/Encoder wrap the package's
+// behaviour for streaming use, mirroring json.Decoder/json.Encoder.
 //
 // To avoid recursive calls to MarshalJSON/UnmarshalJSON, use the following
 // pattern:
@@ -31,6 +46,8 @@ import (
 	"errors"
 	"fmt"
 	"reflect"
+
+	"github.com/Brawaru/j2n/internal/overflow"
 )
 
 type UnknownFields map[string]*json.RawMessage
@@ -47,7 +64,20 @@ var unknownFieldsType = reflect.TypeOf((UnknownFields)(nil))
 //
 //	map[string]*json.RawMessage
 //
+// If v also contains a field of type FieldSet tagged `json:"-"`, it is
+// populated with the presence of every JSON key in data, so that callers can
+// distinguish a missing field from one that was explicitly set to null or to
+// its zero value. See FieldSet for details.
 func UnmarshalJSON(data []byte, v interface{}) error {
+	return unmarshalJSON(data, v, codec)
+}
+
+// unmarshalJSON is the shared implementation behind UnmarshalJSON,
+// UnmarshalJSONWith and Decoder.Decode. c is used for the full marshal/
+// unmarshal round trips of v itself; bookkeeping of the raw overflow keys is
+// always done with encoding/json, since UnknownFields is defined in terms of
+// its *json.RawMessage values.
+func unmarshalJSON(data []byte, v interface{}, c Codec) error {
 	overflow, err := resetOverflowMap(v)
 	if err != nil {
 		return err
@@ -57,11 +87,11 @@ func UnmarshalJSON(data []byte, v interface{}) error {
 		return err
 	}
 
-	if err := json.Unmarshal(data, v); err != nil {
+	if err := c.Unmarshal(data, v); err != nil {
 		return err
 	}
 
-	namedFieldsJSON, err := json.Marshal(v)
+	namedFieldsJSON, err := c.Marshal(v)
 	if err != nil {
 		return err
 	}
@@ -75,6 +105,14 @@ func UnmarshalJSON(data []byte, v interface{}) error {
 		delete(overflow, k)
 	}
 
+	if err := populatePresence(data, v); err != nil {
+		return err
+	}
+
+	if err := unmarshalNested(data, v, c); err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -82,22 +120,22 @@ func UnmarshalJSON(data []byte, v interface{}) error {
 //
 // This behaves exactly like json.Marshal, but ensures that any extra fields
 // mentioned in v.Overflow are output alongside the explicitly named struct
-// fields.
+// fields. Any field of v (or slice/map of such) that is itself a struct
+// declaring its own UnknownFields field has its unknown keys preserved too,
+// however deeply nested.
 //
 // It expects v to contain a field named 'Overflow' of type
 //
 // 	map[string]*json.RawMessage
 //
 func MarshalJSON(v interface{}) ([]byte, error) {
-	result := make(map[string]*json.RawMessage)
-
-	// Do a round trip of the named fields into a map[string]*json_helpers.RawMessage
-	namedFieldsJSON, err := json.Marshal(v)
-	if err != nil {
-		return nil, err
-	}
+	return marshalJSON(v, codec)
+}
 
-	err = json.Unmarshal(namedFieldsJSON, &result)
+// marshalJSON is the shared implementation behind MarshalJSON and
+// MarshalJSONWith.
+func marshalJSON(v interface{}, c Codec) ([]byte, error) {
+	result, err := marshalNamedFields(v, c)
 	if err != nil {
 		return nil, err
 	}
@@ -142,43 +180,5 @@ func getOverflowMap(v interface{}) (UnknownFields, error) {
 }
 
 func getOverflowFieldValue(v interface{}) (reflect.Value, error) {
-	value := reflect.ValueOf(v)
-
-	// Unwrap the pointer if necessary
-	if value.Type().Kind() == reflect.Ptr {
-		value = value.Elem()
-	}
-
-	// Check that we're dealing with a struct
-	if value.Type().Kind() != reflect.Struct {
-		errText := fmt.Sprintf("expected struct, got %s", value.Type().Kind())
-		return reflect.Value{}, errors.New(errText)
-	}
-
-	var field reflect.Value
-	var fieldIndex = -1
-	for i := 0; i < value.NumField(); i++ {
-		f := value.Field(i)
-
-		if f.Type() == unknownFieldsType {
-			if fieldIndex == -1 {
-				field = f
-				fieldIndex = i
-			} else {
-				return reflect.Value{}, errors.New("multiple unknown fields")
-			}
-		}
-	}
-
-	// Check that we actually found the field
-	if fieldIndex == -1 {
-		return reflect.Value{}, errors.New("field is not defined")
-	}
-
-	// And that it has a tag ensuring that it is omitted from the JSON output
-	if val, ok := value.Type().Field(fieldIndex).Tag.Lookup("json"); !ok || val != "-" {
-		return reflect.Value{}, errors.New("unknown fields must be ignored by the standard marshaller (use `json:\"-\"`)")
-	}
-
-	return field, nil
+	return overflow.FieldValue(v, unknownFieldsType, "json")
 }
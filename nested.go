@@ -0,0 +1,329 @@
+package j2n
+
+import (
+	"encoding/json"
+	"reflect"
+)
+
+// unmarshalNested looks for fields of v (a struct, or pointer to one) that
+// themselves contain, directly or through further nesting, a value with its
+// own UnknownFields field, and applies the overflow-preserving unmarshal
+// logic at each one found. This lets unknown keys survive a round trip at
+// every level of nesting, not just the outermost struct.
+func unmarshalNested(data []byte, v interface{}, c Codec) error {
+	value := reflect.ValueOf(v)
+	if value.Kind() == reflect.Ptr {
+		value = value.Elem()
+	}
+	if value.Kind() != reflect.Struct {
+		return nil
+	}
+
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	t := value.Type()
+	for i := 0; i < t.NumField(); i++ {
+		key, ok := jsonKeyForField(t.Field(i))
+		if !ok {
+			continue
+		}
+
+		fieldData, ok := raw[key]
+		if !ok {
+			continue
+		}
+
+		if err := unmarshalNestedValue(fieldData, value.Field(i), c); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// unmarshalNestedValue descends through pointers, slice/array elements and
+// map values to reach the struct values within fv, applying the
+// overflow-preserving unmarshal logic to each one that declares its own
+// UnknownFields field.
+func unmarshalNestedValue(data json.RawMessage, fv reflect.Value, c Codec) error {
+	switch fv.Kind() {
+	case reflect.Ptr:
+		if fv.IsNil() {
+			return nil
+		}
+		return unmarshalNestedValue(data, fv.Elem(), c)
+
+	case reflect.Struct:
+		if !typeHasOverflow(fv.Type()) {
+			// fv has no UnknownFields field anywhere beneath it (e.g. it's a
+			// time.Time or similarly non-object-shaped value with its own
+			// json.Unmarshaler); it was already decoded correctly by the
+			// standard unmarshal, so there's nothing to patch up here.
+			return nil
+		}
+		return unmarshalStructOverflow(data, fv, c)
+
+	case reflect.Slice, reflect.Array:
+		if !isStructElemType(fv.Type().Elem()) {
+			return nil
+		}
+
+		var elems []json.RawMessage
+		if err := json.Unmarshal(data, &elems); err != nil {
+			return nil
+		}
+
+		for i := 0; i < fv.Len() && i < len(elems); i++ {
+			if err := unmarshalNestedValue(elems[i], fv.Index(i), c); err != nil {
+				return err
+			}
+		}
+
+		return nil
+
+	case reflect.Map:
+		if fv.Type().Key().Kind() != reflect.String || !isStructElemType(fv.Type().Elem()) {
+			return nil
+		}
+
+		var elems map[string]json.RawMessage
+		if err := json.Unmarshal(data, &elems); err != nil {
+			return nil
+		}
+
+		for _, key := range fv.MapKeys() {
+			elemData, ok := elems[key.String()]
+			if !ok {
+				continue
+			}
+
+			// Map values aren't addressable, so mutate a settable copy and
+			// write it back.
+			elem := reflect.New(fv.Type().Elem()).Elem()
+			elem.Set(fv.MapIndex(key))
+			if err := unmarshalNestedValue(elemData, elem, c); err != nil {
+				return err
+			}
+			fv.SetMapIndex(key, elem)
+		}
+
+		return nil
+
+	default:
+		return nil
+	}
+}
+
+// unmarshalStructOverflow applies the overflow-preserving unmarshal logic to
+// the addressable struct value fv if its type declares an UnknownFields
+// field, then recurses into fv's own fields to handle further nesting.
+func unmarshalStructOverflow(data json.RawMessage, fv reflect.Value, c Codec) error {
+	if !fv.CanAddr() {
+		return nil
+	}
+	ptr := fv.Addr().Interface()
+
+	if _, err := getOverflowFieldValue(ptr); err == nil {
+		return unmarshalJSON(data, ptr, c)
+	}
+
+	return unmarshalNested(data, ptr, c)
+}
+
+// marshalNestedValue descends through pointers, slice/array elements and
+// map values to reach the struct values within fv, re-encoding each one that
+// declares its own UnknownFields field (directly or through further
+// nesting) so its preserved unknown keys are included. ok reports whether
+// data was produced by this recursive logic; if false, the caller should
+// fall back to encoding fv the ordinary way.
+func marshalNestedValue(fv reflect.Value, c Codec) (data json.RawMessage, ok bool, err error) {
+	switch fv.Kind() {
+	case reflect.Ptr:
+		if fv.IsNil() {
+			return nil, false, nil
+		}
+		return marshalNestedValue(fv.Elem(), c)
+
+	case reflect.Struct:
+		if !typeHasOverflow(fv.Type()) {
+			// Same reasoning as in unmarshalNestedValue: nothing to patch up
+			// for a struct that has no UnknownFields field anywhere beneath
+			// it, so fall back to the ordinary encoding already captured by
+			// the caller.
+			return nil, false, nil
+		}
+		return marshalStructOverflow(fv, c)
+
+	case reflect.Slice, reflect.Array:
+		if !isStructElemType(fv.Type().Elem()) {
+			return nil, false, nil
+		}
+
+		elems := make([]json.RawMessage, fv.Len())
+		for i := 0; i < fv.Len(); i++ {
+			elems[i], err = marshalElement(fv.Index(i), c)
+			if err != nil {
+				return nil, false, err
+			}
+		}
+
+		data, err = json.Marshal(elems)
+		return data, true, err
+
+	case reflect.Map:
+		if fv.Type().Key().Kind() != reflect.String || !isStructElemType(fv.Type().Elem()) {
+			return nil, false, nil
+		}
+
+		out := make(map[string]json.RawMessage, fv.Len())
+		for _, key := range fv.MapKeys() {
+			out[key.String()], err = marshalElement(fv.MapIndex(key), c)
+			if err != nil {
+				return nil, false, err
+			}
+		}
+
+		data, err = json.Marshal(out)
+		return data, true, err
+
+	default:
+		return nil, false, nil
+	}
+}
+
+// marshalElement encodes fv, using the overflow-preserving logic if
+// available and falling back to a plain json.Marshal otherwise.
+func marshalElement(fv reflect.Value, c Codec) (json.RawMessage, error) {
+	if data, ok, err := marshalNestedValue(fv, c); err != nil {
+		return nil, err
+	} else if ok {
+		return data, nil
+	}
+
+	return json.Marshal(fv.Interface())
+}
+
+// marshalStructOverflow encodes the struct value fv, merging in its own
+// preserved unknown keys (and any found through further nesting) if fv's
+// type declares an UnknownFields field.
+func marshalStructOverflow(fv reflect.Value, c Codec) (json.RawMessage, bool, error) {
+	iface := fv.Interface()
+	if fv.CanAddr() {
+		iface = fv.Addr().Interface()
+	}
+
+	if _, err := getOverflowFieldValue(iface); err == nil {
+		data, err := marshalJSON(iface, c)
+		if err != nil {
+			return nil, false, err
+		}
+		return data, true, nil
+	}
+
+	result, err := marshalNamedFields(iface, c)
+	if err != nil {
+		return nil, false, err
+	}
+
+	data, err := json.Marshal(result)
+	if err != nil {
+		return nil, false, err
+	}
+
+	return data, true, nil
+}
+
+// marshalNamedFields returns the JSON encoding of v's named fields (the
+// normal json.Marshal output of v, minus any `json:"-"` fields such as
+// UnknownFields), with every nested struct, slice or map field patched to
+// include its own preserved unknown keys.
+func marshalNamedFields(v interface{}, c Codec) (map[string]*json.RawMessage, error) {
+	namedFieldsJSON, err := c.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(map[string]*json.RawMessage)
+	if err := json.Unmarshal(namedFieldsJSON, &result); err != nil {
+		return nil, err
+	}
+
+	value := reflect.ValueOf(v)
+	if value.Kind() == reflect.Ptr {
+		value = value.Elem()
+	}
+	if value.Kind() != reflect.Struct {
+		return result, nil
+	}
+
+	t := value.Type()
+	for i := 0; i < t.NumField(); i++ {
+		key, ok := jsonKeyForField(t.Field(i))
+		if !ok {
+			continue
+		}
+
+		data, ok, err := marshalNestedValue(value.Field(i), c)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			result[key] = &data
+		}
+	}
+
+	return result, nil
+}
+
+// isStructElemType reports whether t is a struct, or a pointer to one.
+func isStructElemType(t reflect.Type) bool {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t.Kind() == reflect.Struct
+}
+
+// typeHasOverflow reports whether t (a struct, or a pointer/slice/array/map
+// thereof) declares an UnknownFields field, directly or through further
+// nesting. Structs for which this is false carry no overflow bucket
+// anywhere in their tree, so they must be left to the standard
+// json.Marshal/json.Unmarshal handling instead of the overflow-preserving
+// one - which is required for types such as time.Time that marshal to
+// something other than a JSON object.
+func typeHasOverflow(t reflect.Type) bool {
+	return typeHasOverflowSeen(t, make(map[reflect.Type]bool))
+}
+
+func typeHasOverflowSeen(t reflect.Type, seen map[reflect.Type]bool) bool {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		if seen[t] {
+			return false
+		}
+		seen[t] = true
+
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			if f.Type == unknownFieldsType {
+				return true
+			}
+			if typeHasOverflowSeen(f.Type, seen) {
+				return true
+			}
+		}
+		return false
+
+	case reflect.Slice, reflect.Array, reflect.Map:
+		return typeHasOverflowSeen(t.Elem(), seen)
+
+	default:
+		return false
+	}
+}
@@ -0,0 +1,121 @@
+package j2n
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+type nestedPerson struct {
+	Name string        `json:"name"`
+	Rest UnknownFields `json:"-"`
+}
+
+type nestedCat struct {
+	Owner   nestedPerson            `json:"owner"`
+	Friends []nestedPerson          `json:"friends"`
+	Pets    map[string]nestedPerson `json:"pets"`
+	Rest    UnknownFields           `json:"-"`
+}
+
+func TestNestedUnknownFieldsRoundTrip(t *testing.T) {
+	input := `{
+		"owner": {"name": "Alice", "age": 42},
+		"friends": [{"name": "Bob", "city": "NYC"}],
+		"pets": {"fido": {"name": "Fido", "breed": "Lab"}},
+		"extra": "top-level"
+	}`
+
+	var cat nestedCat
+	if err := UnmarshalJSON([]byte(input), &cat); err != nil {
+		t.Fatal(err)
+	}
+
+	if raw, ok := cat.Rest["extra"]; !ok || raw == nil {
+		t.Error("missing top-level overflow key 'extra'")
+	}
+	if raw, ok := cat.Owner.Rest["age"]; !ok || raw == nil {
+		t.Error("missing nested overflow key 'owner.age'")
+	}
+	if len(cat.Friends) != 1 {
+		t.Fatalf("got %d friends, want 1", len(cat.Friends))
+	}
+	if raw, ok := cat.Friends[0].Rest["city"]; !ok || raw == nil {
+		t.Error("missing nested overflow key 'friends[0].city'")
+	}
+	fido, ok := cat.Pets["fido"]
+	if !ok {
+		t.Fatal("missing pets[\"fido\"]")
+	}
+	if raw, ok := fido.Rest["breed"]; !ok || raw == nil {
+		t.Error("missing nested overflow key 'pets[\"fido\"].breed'")
+	}
+
+	out, err := MarshalJSON(cat)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var roundTripped map[string]json.RawMessage
+	if err := json.Unmarshal(out, &roundTripped); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := roundTripped["extra"]; !ok {
+		t.Error("marshaled output missing top-level key 'extra'")
+	}
+
+	var owner map[string]json.RawMessage
+	if err := json.Unmarshal(roundTripped["owner"], &owner); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := owner["age"]; !ok {
+		t.Error("marshaled output missing nested key 'owner.age'")
+	}
+}
+
+// nestedEvent has a struct-kind field (time.Time) that marshals to a JSON
+// string rather than an object, and carries no UnknownFields field of its
+// own. This must round trip via the ordinary encoding/json handling, not
+// the overflow-preserving one.
+type nestedEvent struct {
+	Name string        `json:"name"`
+	When time.Time     `json:"when"`
+	Rest UnknownFields `json:"-"`
+}
+
+func TestNonObjectShapedNestedFieldRoundTrip(t *testing.T) {
+	input := `{"name": "launch", "when": "2020-01-02T03:04:05Z", "extra": true}`
+
+	var event nestedEvent
+	if err := UnmarshalJSON([]byte(input), &event); err != nil {
+		t.Fatal(err)
+	}
+
+	want := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	if !event.When.Equal(want) {
+		t.Errorf("When = %v, want %v", event.When, want)
+	}
+	if raw, ok := event.Rest["extra"]; !ok || raw == nil {
+		t.Error("missing top-level overflow key 'extra'")
+	}
+
+	out, err := MarshalJSON(event)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var roundTripped map[string]json.RawMessage
+	if err := json.Unmarshal(out, &roundTripped); err != nil {
+		t.Fatal(err)
+	}
+	var when time.Time
+	if err := json.Unmarshal(roundTripped["when"], &when); err != nil {
+		t.Fatal(err)
+	}
+	if !when.Equal(want) {
+		t.Errorf("marshaled 'when' = %v, want %v", when, want)
+	}
+	if _, ok := roundTripped["extra"]; !ok {
+		t.Error("marshaled output missing top-level key 'extra'")
+	}
+}
@@ -0,0 +1,149 @@
+package j2n
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// Presence describes whether a JSON key was present in an UnmarshalJSON
+// input, and if so, whether its value was the JSON literal null.
+type Presence int
+
+const (
+	// PresenceSet indicates the key was present with a non-null value.
+	PresenceSet Presence = iota + 1
+	// PresenceNull indicates the key was present with the value null.
+	PresenceNull
+)
+
+// FieldSet records, per JSON key, whether that key was present in the input
+// and whether it was null. Keys absent from the input are simply absent
+// from the map.
+//
+// A struct opts into presence tracking by adding a field of this type
+// tagged `json:"-"`, e.g.
+//
+//	type CatData struct {
+//		Name     string        `json:"name"`
+//		Rest     UnknownFields `json:"-"`
+//		Presence FieldSet      `json:"-"`
+//	}
+type FieldSet map[string]Presence
+
+// Has reports whether the JSON key was present in the input, regardless of
+// whether its value was null.
+func (fs FieldSet) Has(key string) bool {
+	_, ok := fs[key]
+	return ok
+}
+
+// IsNull reports whether the JSON key was present in the input with the
+// value null.
+func (fs FieldSet) IsNull(key string) bool {
+	return fs[key] == PresenceNull
+}
+
+var fieldSetType = reflect.TypeOf((FieldSet)(nil))
+
+// populatePresence fills in v's FieldSet field, if it has one, with the
+// presence of every key found in data. It is a no-op if v does not have a
+// FieldSet field, since presence tracking is opt-in.
+func populatePresence(data []byte, v interface{}) error {
+	field, ok, err := getPresenceFieldValue(v)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return nil
+	}
+
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	keys := jsonKeySet(v)
+
+	fields := make(FieldSet, len(raw))
+	for key, rawValue := range raw {
+		if !keys[key] {
+			continue
+		}
+
+		if string(rawValue) == "null" {
+			fields[key] = PresenceNull
+		} else {
+			fields[key] = PresenceSet
+		}
+	}
+
+	field.Set(reflect.ValueOf(fields))
+	return nil
+}
+
+// getPresenceFieldValue returns the settable reflect.Value of v's FieldSet
+// field. Unlike the UnknownFields field, a FieldSet field is optional: if
+// none is found, ok is false and err is nil.
+func getPresenceFieldValue(v interface{}) (value reflect.Value, ok bool, err error) {
+	rv := reflect.ValueOf(v)
+
+	if rv.Type().Kind() == reflect.Ptr {
+		rv = rv.Elem()
+	}
+
+	if rv.Type().Kind() != reflect.Struct {
+		return reflect.Value{}, false, fmt.Errorf("expected struct, got %s", rv.Type().Kind())
+	}
+
+	for i := 0; i < rv.NumField(); i++ {
+		f := rv.Field(i)
+		if f.Type() == fieldSetType {
+			return f, true, nil
+		}
+	}
+
+	return reflect.Value{}, false, nil
+}
+
+// jsonKeySet returns the set of JSON keys that encoding/json would use for
+// the fields of v's struct type.
+func jsonKeySet(v interface{}) map[string]bool {
+	t := reflect.TypeOf(v)
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	keys := make(map[string]bool, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		key, ok := jsonKeyForField(t.Field(i))
+		if !ok {
+			continue
+		}
+
+		keys[key] = true
+	}
+
+	return keys
+}
+
+// jsonKeyForField returns the JSON key that encoding/json would use for
+// field, and whether the field participates in JSON encoding at all. An
+// explicit name in the `json` tag wins, a tag of "-" excludes the field, and
+// otherwise the field name itself is used.
+func jsonKeyForField(field reflect.StructField) (key string, ok bool) {
+	tag, hasTag := field.Tag.Lookup("json")
+	if hasTag && tag == "-" {
+		return "", false
+	}
+
+	key = field.Name
+	if hasTag {
+		if name := strings.Split(tag, ",")[0]; name != "" {
+			key = name
+		}
+	}
+
+	return key, true
+}
@@ -0,0 +1,33 @@
+package j2n
+
+import "testing"
+
+type presenceCatData struct {
+	Name     string        `json:"name"`
+	Age      int           `json:"age"`
+	Rest     UnknownFields `json:"-"`
+	Presence FieldSet      `json:"-"`
+}
+
+func TestPresenceKeyedByJSONKey(t *testing.T) {
+	var cat presenceCatData
+	if err := UnmarshalJSON([]byte(`{"name":"Tom","age":null}`), &cat); err != nil {
+		t.Fatal(err)
+	}
+
+	if !cat.Presence.Has("name") {
+		t.Error("Has(\"name\") = false, want true")
+	}
+	if cat.Presence.Has("Name") {
+		t.Error("Has(\"Name\") = true, want false (Go field name should not be a key)")
+	}
+	if !cat.Presence.Has("age") {
+		t.Error("Has(\"age\") = false, want true")
+	}
+	if !cat.Presence.IsNull("age") {
+		t.Error("IsNull(\"age\") = false, want true")
+	}
+	if cat.Presence.Has("color") {
+		t.Error("Has(\"color\") = true, want false (field absent from input)")
+	}
+}
@@ -0,0 +1,81 @@
+package j2n
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Options configures the extra validation UnmarshalJSONStrict performs on
+// top of the overflow-preserving default.
+type Options struct {
+	// RequiredFields lists JSON keys that must be present in the input.
+	// Missing keys are reported together in a single error.
+	RequiredFields []string
+
+	// DisallowUnknown causes UnmarshalJSONStrict to fail if the input
+	// contains any key not mapped to a named field of v, reporting every
+	// offending key in a single error, instead of routing them into the
+	// overflow map.
+	DisallowUnknown bool
+
+	// OnUnknown, if set, is called once for every key that is not mapped
+	// to a named field of v, in sorted key order, before the overflow map
+	// is populated. Returning an error aborts UnmarshalJSONStrict with
+	// that error.
+	OnUnknown func(key string, raw json.RawMessage) error
+}
+
+// UnmarshalJSONStrict behaves like UnmarshalJSON, but additionally enforces
+// opts: it can require that specific keys be present, forbid unknown keys
+// entirely, or invoke a callback for every key that would otherwise be
+// silently routed into the overflow map.
+//
+// Validation runs against the raw JSON input before v is touched, so on a
+// rejected input v is left exactly as the caller passed it in.
+func UnmarshalJSONStrict(data []byte, v interface{}, opts Options) error {
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	if len(opts.RequiredFields) > 0 {
+		var missing []string
+		for _, name := range opts.RequiredFields {
+			if _, ok := raw[name]; !ok {
+				missing = append(missing, name)
+			}
+		}
+		if len(missing) > 0 {
+			sort.Strings(missing)
+			return fmt.Errorf("missing required field(s): %s", strings.Join(missing, ", "))
+		}
+	}
+
+	if opts.DisallowUnknown || opts.OnUnknown != nil {
+		knownKeys := jsonKeySet(v)
+
+		var unknown []string
+		for key := range raw {
+			if !knownKeys[key] {
+				unknown = append(unknown, key)
+			}
+		}
+		sort.Strings(unknown)
+
+		if opts.DisallowUnknown && len(unknown) > 0 {
+			return fmt.Errorf("unknown field(s): %s", strings.Join(unknown, ", "))
+		}
+
+		if opts.OnUnknown != nil {
+			for _, key := range unknown {
+				if err := opts.OnUnknown(key, raw[key]); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	return UnmarshalJSON(data, v)
+}
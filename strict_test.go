@@ -0,0 +1,61 @@
+package j2n
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+type strictCatData struct {
+	Name string        `json:"name"`
+	Rest UnknownFields `json:"-"`
+}
+
+func TestUnmarshalJSONStrictRequiredFields(t *testing.T) {
+	var cat strictCatData
+	err := UnmarshalJSONStrict([]byte(`{"name":"Tom"}`), &cat, Options{
+		RequiredFields: []string{"name", "age"},
+	})
+	if err == nil {
+		t.Fatal("expected an error for a missing required field")
+	}
+}
+
+func TestUnmarshalJSONStrictDisallowUnknownLeavesVUntouched(t *testing.T) {
+	cat := strictCatData{Name: "placeholder"}
+	err := UnmarshalJSONStrict([]byte(`{"name":"Tom","extra":1}`), &cat, Options{
+		DisallowUnknown: true,
+	})
+	if err == nil {
+		t.Fatal("expected an error for an unknown field")
+	}
+	if cat.Name != "placeholder" {
+		t.Errorf("v was mutated despite rejection: Name = %q, want \"placeholder\"", cat.Name)
+	}
+	if cat.Rest != nil {
+		t.Errorf("v was mutated despite rejection: Rest = %v, want nil", cat.Rest)
+	}
+}
+
+func TestUnmarshalJSONStrictOnUnknownSeesNullRaw(t *testing.T) {
+	var seenRaw json.RawMessage
+	var cat strictCatData
+	err := UnmarshalJSONStrict([]byte(`{"name":"Tom","extra":null}`), &cat, Options{
+		OnUnknown: func(key string, raw json.RawMessage) error {
+			if key == "extra" {
+				seenRaw = raw
+			}
+			return nil
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(seenRaw) != "null" {
+		t.Errorf("OnUnknown saw raw = %q, want literal \"null\"", seenRaw)
+	}
+
+	var decoded interface{}
+	if err := json.Unmarshal(seenRaw, &decoded); err != nil {
+		t.Errorf("json.Unmarshal(seenRaw, ...) failed: %v", err)
+	}
+}
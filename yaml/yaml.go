@@ -0,0 +1,141 @@
+// Package yaml mirrors package j2n's overflow-preserving semantics for YAML:
+// any mapping keys that are not marshaled directly into the fields of a
+// struct are put into a field with type UnknownFields, so they survive an
+// Unmarshal/Marshal round trip.
+//
+// Use it exactly like j2n itself, but with `yaml:"-"` in place of
+// `json:"-"`:
+//
+//  type CatData struct {
+//  	Name string        `yaml:"name"`
+//  	Rest UnknownFields `yaml:"-"`
+//  }
+package yaml
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/Brawaru/j2n/internal/overflow"
+	"gopkg.in/yaml.v3"
+)
+
+// UnknownFields holds the YAML mapping keys that were not explicitly named
+// in the destination struct, preserved as a mapping node so they survive a
+// round trip even though their shape is unknown.
+type UnknownFields = yaml.Node
+
+var unknownFieldsType = reflect.TypeOf(UnknownFields{})
+
+// Unmarshal parses the YAML-encoded data into the struct pointed to by v.
+//
+// This behaves exactly like yaml.Unmarshal, but any extra mapping keys that
+// are not explicitly named in the struct are collected into the struct's
+// UnknownFields field.
+func Unmarshal(data []byte, v interface{}) error {
+	field, err := getOverflowFieldValue(v)
+	if err != nil {
+		return err
+	}
+
+	doc, err := mappingNode(data)
+	if err != nil {
+		return err
+	}
+
+	if err := yaml.Unmarshal(data, v); err != nil {
+		return err
+	}
+
+	namedFieldsData, err := yaml.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	namedDoc, err := mappingNode(namedFieldsData)
+	if err != nil {
+		return err
+	}
+
+	named := mappingKeys(namedDoc)
+
+	overflow := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+	for i := 0; i+1 < len(doc.Content); i += 2 {
+		key := doc.Content[i]
+		if named[key.Value] {
+			continue
+		}
+		overflow.Content = append(overflow.Content, key, doc.Content[i+1])
+	}
+
+	field.Set(reflect.ValueOf(*overflow))
+	return nil
+}
+
+// Marshal returns the YAML encoding of v, which must be a struct.
+//
+// This behaves exactly like yaml.Marshal, but ensures that any extra
+// mapping keys held in v's UnknownFields field are output alongside the
+// explicitly named struct fields.
+func Marshal(v interface{}) ([]byte, error) {
+	field, err := getOverflowFieldValue(v)
+	if err != nil {
+		return nil, err
+	}
+	overflow := field.Interface().(UnknownFields)
+
+	namedFieldsData, err := yaml.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := mappingNode(namedFieldsData)
+	if err != nil {
+		return nil, err
+	}
+	if result.Kind == 0 {
+		result.Kind = yaml.MappingNode
+		result.Tag = "!!map"
+	}
+
+	named := mappingKeys(result)
+
+	for i := 0; i+1 < len(overflow.Content); i += 2 {
+		key := overflow.Content[i].Value
+		if named[key] {
+			return nil, fmt.Errorf("named field present in overflow: '%s'", key)
+		}
+		result.Content = append(result.Content, overflow.Content[i], overflow.Content[i+1])
+	}
+
+	return yaml.Marshal(result)
+}
+
+// mappingNode decodes data and returns its top-level mapping node, unwrapped
+// from the document node yaml.Unmarshal produces.
+func mappingNode(data []byte) (*yaml.Node, error) {
+	var root yaml.Node
+	if err := yaml.Unmarshal(data, &root); err != nil {
+		return nil, err
+	}
+
+	doc := &root
+	if doc.Kind == yaml.DocumentNode && len(doc.Content) > 0 {
+		doc = doc.Content[0]
+	}
+
+	return doc, nil
+}
+
+// mappingKeys returns the set of scalar keys in a mapping node.
+func mappingKeys(doc *yaml.Node) map[string]bool {
+	keys := make(map[string]bool, len(doc.Content)/2)
+	for i := 0; i+1 < len(doc.Content); i += 2 {
+		keys[doc.Content[i].Value] = true
+	}
+	return keys
+}
+
+func getOverflowFieldValue(v interface{}) (reflect.Value, error) {
+	return overflow.FieldValue(v, unknownFieldsType, "yaml")
+}
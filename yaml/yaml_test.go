@@ -0,0 +1,46 @@
+package yaml
+
+import (
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+type catData struct {
+	Name string        `yaml:"name"`
+	Rest UnknownFields `yaml:"-"`
+}
+
+func TestUnmarshalMarshalRoundTrip(t *testing.T) {
+	input := "name: Tom\nage: 9\n"
+
+	var cat catData
+	if err := Unmarshal([]byte(input), &cat); err != nil {
+		t.Fatal(err)
+	}
+
+	if cat.Name != "Tom" {
+		t.Errorf("Name = %q, want Tom", cat.Name)
+	}
+
+	keys := mappingKeys(&cat.Rest)
+	if !keys["age"] {
+		t.Error("missing overflow key 'age'")
+	}
+
+	out, err := Marshal(cat)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var roundTripped map[string]interface{}
+	if err := yaml.Unmarshal(out, &roundTripped); err != nil {
+		t.Fatal(err)
+	}
+	if roundTripped["name"] != "Tom" {
+		t.Errorf("marshaled output name = %v, want Tom", roundTripped["name"])
+	}
+	if _, ok := roundTripped["age"]; !ok {
+		t.Error("marshaled output missing overflow key 'age'")
+	}
+}